@@ -0,0 +1,168 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gotify/server/v2/auth"
+	"github.com/gotify/server/v2/model"
+)
+
+// BulkMessageRequest is the body of POST /message/bulk.
+type BulkMessageRequest struct {
+	IDs    []uint `json:"ids" binding:"required"`
+	Action string `json:"action" binding:"required"`
+	At     string `json:"at"`
+}
+
+// BulkMessageResult reports whether a single message within a bulk request
+// succeeded, so partially-invalid batches don't fail hard.
+type BulkMessageResult struct {
+	ID      uint   `json:"id"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// BulkMessage performs a delete, postpone, or clear_postpone action on a
+// batch of messages in one round trip.
+// swagger:operation POST /message/bulk message bulkMessage
+//
+// Perform a bulk action on a batch of messages.
+//
+//	---
+//	consumes: [application/json]
+//	produces: [application/json]
+//	security: [clientTokenAuthorizationHeader: [], clientTokenHeader: [], clientTokenQuery: [], basicAuth: []]
+//	parameters:
+//	- name: body
+//	  in: body
+//	  description: the ids and action to perform
+//	  required: true
+//	  schema:
+//	    type: object
+//	    properties:
+//	      ids:
+//	        type: array
+//	        items:
+//	          type: integer
+//	      action:
+//	        type: string
+//	        enum: [delete, postpone, clear_postpone]
+//	      at:
+//	        type: string
+//	responses:
+//	  200:
+//	    description: Ok
+//	  400:
+//	    description: Bad Request
+//	    schema:
+//	        $ref: "#/definitions/Error"
+//	  401:
+//	    description: Unauthorized
+//	    schema:
+//	        $ref: "#/definitions/Error"
+func (a *MessageAPI) BulkMessage(ctx *gin.Context) {
+	var body BulkMessageRequest
+	if err := ctx.Bind(&body); err != nil {
+		return
+	}
+
+	userID := auth.GetUserID(ctx)
+	owned, err := a.ownedMessagesByID(userID, body.IDs)
+	if success := successOrAbort(ctx, 500, err); !success {
+		return
+	}
+	ids := ownedIDs(body.IDs, owned)
+
+	var succeeded []uint
+	switch body.Action {
+	case "delete":
+		succeeded, err = a.DB.DeleteMessagesByIDs(userID, ids)
+		if success := successOrAbort(ctx, 500, err); !success {
+			return
+		}
+		for _, id := range succeeded {
+			a.Scheduler.DeleteMessageSchedule(owned[id])
+		}
+	case "postpone":
+		postponedAt, parseErr := time.Parse(time.RFC3339, body.At)
+		if parseErr != nil {
+			ctx.AbortWithError(400, errors.New("at must be a valid RFC3339 time for the postpone action"))
+			return
+		}
+		if postponedAt.Before(timeNow()) {
+			ctx.AbortWithError(400, errors.New("postponed time must be in the future"))
+			return
+		}
+		succeeded, err = a.DB.UpdateMessagesPostponement(userID, ids, &postponedAt)
+		if success := successOrAbort(ctx, 500, err); !success {
+			return
+		}
+		for _, id := range succeeded {
+			a.Scheduler.DeleteMessageSchedule(owned[id])
+			a.Scheduler.ScheduleMessage(id, postponedAt)
+		}
+	case "clear_postpone":
+		succeeded, err = a.DB.UpdateMessagesPostponement(userID, ids, nil)
+		if success := successOrAbort(ctx, 500, err); !success {
+			return
+		}
+		for _, id := range succeeded {
+			a.Scheduler.DeleteMessageSchedule(owned[id])
+		}
+	default:
+		ctx.AbortWithError(400, fmt.Errorf("unknown action %q", body.Action))
+		return
+	}
+
+	ctx.JSON(200, bulkResults(body.IDs, owned, succeeded))
+}
+
+// ownedMessagesByID looks up the given ids in one query and keeps only the
+// ones belonging to userID.
+func (a *MessageAPI) ownedMessagesByID(userID uint, ids []uint) (map[uint]*model.Message, error) {
+	messages, err := a.DB.GetMessagesByIDs(userID, ids)
+	if err != nil {
+		return nil, err
+	}
+	owned := make(map[uint]*model.Message, len(messages))
+	for _, msg := range messages {
+		owned[msg.ID] = msg
+	}
+	return owned, nil
+}
+
+func ownedIDs(ids []uint, owned map[uint]*model.Message) []uint {
+	filtered := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := owned[id]; ok {
+			filtered = append(filtered, id)
+		}
+	}
+	return filtered
+}
+
+// bulkResults builds a per-ID result in the original request order: ids that
+// weren't owned by the user are reported as failures without ever reaching
+// the database mutation.
+func bulkResults(ids []uint, owned map[uint]*model.Message, succeeded []uint) []BulkMessageResult {
+	succeededSet := make(map[uint]bool, len(succeeded))
+	for _, id := range succeeded {
+		succeededSet[id] = true
+	}
+
+	results := make([]BulkMessageResult, len(ids))
+	for i, id := range ids {
+		switch {
+		case succeededSet[id]:
+			results[i] = BulkMessageResult{ID: id, Success: true}
+		case owned[id] == nil:
+			results[i] = BulkMessageResult{ID: id, Success: false, Error: "message does not exist"}
+		default:
+			results[i] = BulkMessageResult{ID: id, Success: false, Error: "failed to update message"}
+		}
+	}
+	return results
+}
@@ -27,6 +27,11 @@ type MessageDatabase interface {
 	CreateMessage(message *model.Message) error
 	GetApplicationByToken(token string) (*model.Application, error)
 	UpdateMessagePostponement(id uint, postponedAt *time.Time) error
+	UpdateMessageSchedule(id uint, schedule *string) error
+	GetUserByID(id uint) (*model.User, error)
+	GetMessagesByIDs(userID uint, ids []uint) ([]*model.Message, error)
+	DeleteMessagesByIDs(userID uint, ids []uint) ([]uint, error)
+	UpdateMessagesPostponement(userID uint, ids []uint, postponedAt *time.Time) ([]uint, error)
 }
 
 var timeNow = time.Now
@@ -35,7 +40,7 @@ var timeNow = time.Now
 type MessageAPI struct {
 	DB        MessageDatabase
 	Notifier  Notifier
-	Scheduler scheduler.Scheduler
+	Scheduler *scheduler.Scheduler
 }
 
 type pagingParams struct {
@@ -395,15 +400,147 @@ func (a *MessageAPI) CreateMessage(ctx *gin.Context) {
 		if success := successOrAbort(ctx, 500, a.DB.CreateMessage(msgInternal)); !success {
 			return
 		}
-		if message.PostponedAt != nil {
+		switch {
+		case message.Schedule != nil && strings.TrimSpace(*message.Schedule) != "":
+			if err := a.Scheduler.ScheduleRecurring(msgInternal.ID, *message.Schedule); err != nil {
+				ctx.AbortWithError(400, err)
+				return
+			}
+		case message.PostponedAt != nil:
 			a.Scheduler.ScheduleMessage(msgInternal.ID, *message.PostponedAt)
-		} else {
+		default:
 			a.Notifier.Notify(auth.GetUserID(ctx), scheduler.ToExternalMessage(msgInternal))
 		}
 		ctx.JSON(200, scheduler.ToExternalMessage(msgInternal))
 	}
 }
 
+// UpdateMessageSchedule reconfigures the recurring schedule of a message
+// template.
+// swagger:operation PUT /message/{id}/schedule message updateMessageSchedule
+//
+// Reconfigure the recurring schedule of a message template.
+//
+//	---
+//	consumes: [application/json]
+//	produces: [application/json]
+//	security: [clientTokenAuthorizationHeader: [], clientTokenHeader: [], clientTokenQuery: [], basicAuth: []]
+//	parameters:
+//	- name: id
+//	  in: path
+//	  description: the message id
+//	  required: true
+//	  type: integer
+//	  format: int64
+//	- name: body
+//	  in: body
+//	  description: the new schedule (cron expression or ISO-8601 duration)
+//	  required: true
+//	  schema:
+//	    type: object
+//	    properties:
+//	      schedule:
+//	        type: string
+//	responses:
+//	  200:
+//	    description: Ok
+//	  400:
+//	    description: Bad Request
+//	    schema:
+//	        $ref: "#/definitions/Error"
+//	  401:
+//	    description: Unauthorized
+//	    schema:
+//	        $ref: "#/definitions/Error"
+//	  403:
+//	    description: Forbidden
+//	    schema:
+//	        $ref: "#/definitions/Error"
+//	  404:
+//	    description: Not Found
+//	    schema:
+//	        $ref: "#/definitions/Error"
+func (a *MessageAPI) UpdateMessageSchedule(ctx *gin.Context) {
+	var body struct {
+		Schedule string `json:"schedule" binding:"required"`
+	}
+	if err := ctx.Bind(&body); err != nil {
+		return
+	}
+	withID(ctx, "id", func(id uint) {
+		a.withOwnedMessage(ctx, id, func(msg *model.Message) {
+			a.Scheduler.DeleteRecurringSchedule(msg.ID)
+			if err := a.Scheduler.ScheduleRecurring(msg.ID, body.Schedule); err != nil {
+				ctx.AbortWithError(400, err)
+				return
+			}
+			successOrAbort(ctx, 500, a.DB.UpdateMessageSchedule(id, &body.Schedule))
+		})
+	})
+}
+
+// DeleteMessageSchedule stops a recurring message template without deleting
+// the template itself.
+// swagger:operation DELETE /message/{id}/schedule message deleteMessageSchedule
+//
+// Stop the recurring schedule of a message template.
+//
+//	---
+//	produces: [application/json]
+//	security: [clientTokenAuthorizationHeader: [], clientTokenHeader: [], clientTokenQuery: [], basicAuth: []]
+//	parameters:
+//	- name: id
+//	  in: path
+//	  description: the message id
+//	  required: true
+//	  type: integer
+//	  format: int64
+//	responses:
+//	  200:
+//	    description: Ok
+//	  401:
+//	    description: Unauthorized
+//	    schema:
+//	        $ref: "#/definitions/Error"
+//	  403:
+//	    description: Forbidden
+//	    schema:
+//	        $ref: "#/definitions/Error"
+//	  404:
+//	    description: Not Found
+//	    schema:
+//	        $ref: "#/definitions/Error"
+func (a *MessageAPI) DeleteMessageSchedule(ctx *gin.Context) {
+	withID(ctx, "id", func(id uint) {
+		a.withOwnedMessage(ctx, id, func(msg *model.Message) {
+			a.Scheduler.DeleteRecurringSchedule(msg.ID)
+			successOrAbort(ctx, 500, a.DB.UpdateMessageSchedule(id, nil))
+		})
+	})
+}
+
+// withOwnedMessage looks up a message by id and runs f if it exists and
+// belongs to the authenticated user, aborting the request with 404 otherwise.
+func (a *MessageAPI) withOwnedMessage(ctx *gin.Context, id uint, f func(msg *model.Message)) {
+	msg, err := a.DB.GetMessageByID(id)
+	if success := successOrAbort(ctx, 500, err); !success {
+		return
+	}
+	if msg == nil {
+		ctx.AbortWithError(404, errors.New("message does not exist"))
+		return
+	}
+	app, err := a.DB.GetApplicationByID(msg.ApplicationID)
+	if success := successOrAbort(ctx, 500, err); !success {
+		return
+	}
+	if app == nil || app.UserID != auth.GetUserID(ctx) {
+		ctx.AbortWithError(404, errors.New("message does not exist"))
+		return
+	}
+	f(msg)
+}
+
 // / postponed message
 func (a *MessageAPI) postponeMessage(ctx *gin.Context, postponedAt *time.Time) {
 	withID(ctx, "id", func(id uint) {
@@ -431,22 +568,64 @@ func (a *MessageAPI) postponeMessage(ctx *gin.Context, postponedAt *time.Time) {
 	})
 }
 
+// PostponeMessage postpones a message using an absolute time ("at"), a
+// relative duration ("in"), or a named preset ("preset") resolved in the
+// user's timezone.
+// swagger:operation POST /message/{id}/postpone message postponeMessage
+//
+// Postpone a message.
+//
+//	---
+//	produces: [application/json]
+//	security: [clientTokenAuthorizationHeader: [], clientTokenHeader: [], clientTokenQuery: [], basicAuth: []]
+//	parameters:
+//	- name: id
+//	  in: path
+//	  description: the message id
+//	  required: true
+//	  type: integer
+//	  format: int64
+//	- name: at
+//	  in: query
+//	  description: the RFC3339 time to postpone the message until
+//	  required: false
+//	  type: string
+//	- name: in
+//	  in: query
+//	  description: a Go duration (e.g. 90m, 2h30m) to postpone the message by
+//	  required: false
+//	  type: string
+//	- name: preset
+//	  in: query
+//	  description: a named preset (see GET /snooze/presets) to postpone the message until
+//	  required: false
+//	  type: string
+//	responses:
+//	  200:
+//	    description: Ok
+//	  400:
+//	    description: Bad Request
+//	    schema:
+//	        $ref: "#/definitions/Error"
+//	  401:
+//	    description: Unauthorized
+//	    schema:
+//	        $ref: "#/definitions/Error"
+//	  403:
+//	    description: Forbidden
+//	    schema:
+//	        $ref: "#/definitions/Error"
+//	  404:
+//	    description: Not Found
+//	    schema:
+//	        $ref: "#/definitions/Error"
 func (a *MessageAPI) PostponeMessage(ctx *gin.Context) {
-	at := ctx.Query("at")
-	if at == "" {
-		ctx.AbortWithError(400, errors.New("at parameter is required"))
-		return
-	}
-	postponedAt, err := time.Parse(time.RFC3339, at)
+	postponedAt, err := a.resolveSnoozeTime(ctx)
 	if err != nil {
-		ctx.AbortWithError(400, errors.New("invalid time format. use RFC3339 format"))
-		return
-	}
-	if postponedAt.Before(timeNow()) {
-		ctx.AbortWithError(400, errors.New("postponed time must be in the future"))
+		ctx.AbortWithError(400, err)
 		return
 	}
-	a.postponeMessage(ctx, &postponedAt)
+	a.postponeMessage(ctx, postponedAt)
 }
 
 func (a *MessageAPI) DeleteMessagePostponement(ctx *gin.Context) {
@@ -461,6 +640,7 @@ func toInternalMessage(msg *model.MessageExternal) *model.Message {
 		Title:         msg.Title,
 		Date:          msg.Date,
 		PostponedAt:   msg.PostponedAt,
+		Schedule:      msg.Schedule,
 	}
 	if msg.Priority != nil {
 		res.Priority = *msg.Priority
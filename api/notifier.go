@@ -3,6 +3,10 @@ package api
 import "github.com/gotify/server/v2/model"
 
 // Notifier notifies when a new message was created.
+//
+// Implementations (e.g. stream.API) are expected to fan Notify out to every
+// client registered for userID regardless of transport (WebSocket, SSE,
+// ...); callers in this package never need to know which transports exist.
 type Notifier interface {
 	Notify(userID uint, message *model.MessageExternal)
 }
@@ -0,0 +1,205 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gotify/server/v2/auth"
+	"github.com/gotify/server/v2/model"
+)
+
+// snoozePreset resolves a named snooze option to an absolute time relative
+// to now, in a given timezone.
+type snoozePreset struct {
+	Name string
+	At   func(now time.Time) time.Time
+}
+
+var snoozePresets = []snoozePreset{
+	{"later_today", func(now time.Time) time.Time { return now.Add(3 * time.Hour) }},
+	{"tonight", func(now time.Time) time.Time { return atTimeOnDay(now, 18, 0) }},
+	{"tomorrow_morning", func(now time.Time) time.Time { return atTimeOnDay(now.AddDate(0, 0, 1), 8, 0) }},
+	{"next_week", func(now time.Time) time.Time { return nextWeekdayAt(now, time.Monday, 9, 0) }},
+	{"weekend", func(now time.Time) time.Time { return nextWeekdayAt(now, time.Saturday, 9, 0) }},
+}
+
+func atTimeOnDay(day time.Time, hour, minute int) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, day.Location())
+}
+
+func nextWeekdayAt(now time.Time, weekday time.Weekday, hour, minute int) time.Time {
+	daysUntil := (int(weekday) - int(now.Weekday()) + 7) % 7
+	if daysUntil == 0 {
+		daysUntil = 7
+	}
+	return atTimeOnDay(now.AddDate(0, 0, daysUntil), hour, minute)
+}
+
+// resolvePresets resolves every named snooze preset to an absolute time in
+// loc, relative to now. A preset that would land at or before now is pushed
+// forward by a day so it always refers to the next occurrence.
+func resolvePresets(now time.Time, loc *time.Location) map[string]time.Time {
+	now = now.In(loc)
+	resolved := make(map[string]time.Time, len(snoozePresets))
+	for _, preset := range snoozePresets {
+		at := preset.At(now)
+		if !at.After(now) {
+			at = at.AddDate(0, 0, 1)
+		}
+		resolved[preset.Name] = at
+	}
+	return resolved
+}
+
+// resolveSnoozeTime resolves a postponement time from the "at" (RFC3339),
+// "in" (Go duration) or "preset" (named preset, resolved in the user's
+// timezone) query parameters.
+func (a *MessageAPI) resolveSnoozeTime(ctx *gin.Context) (*time.Time, error) {
+	switch {
+	case ctx.Query("at") != "":
+		at, err := time.Parse(time.RFC3339, ctx.Query("at"))
+		if err != nil {
+			return nil, errors.New("invalid time format. use RFC3339 format")
+		}
+		if at.Before(timeNow()) {
+			return nil, errors.New("postponed time must be in the future")
+		}
+		return &at, nil
+	case ctx.Query("in") != "":
+		duration, err := time.ParseDuration(ctx.Query("in"))
+		if err != nil {
+			return nil, errors.New("invalid duration format, e.g. 90m or 2h30m")
+		}
+		if duration <= 0 {
+			return nil, errors.New("postponed time must be in the future")
+		}
+		at := timeNow().Add(duration)
+		return &at, nil
+	case ctx.Query("preset") != "":
+		loc, err := a.userTimezone(ctx)
+		if err != nil {
+			return nil, err
+		}
+		name := ctx.Query("preset")
+		at, ok := resolvePresets(timeNow(), loc)[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown preset %q", name)
+		}
+		return &at, nil
+	default:
+		return nil, errors.New("one of the at, in or preset parameters is required")
+	}
+}
+
+// userTimezone returns the authenticated user's timezone, falling back to
+// UTC if it isn't set or fails to load.
+func (a *MessageAPI) userTimezone(ctx *gin.Context) (*time.Location, error) {
+	user, err := a.DB.GetUserByID(auth.GetUserID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.Timezone == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(user.Timezone)
+	if err != nil {
+		return time.UTC, nil
+	}
+	return loc, nil
+}
+
+// SnoozeMessage postpones a message using an absolute time, relative
+// duration, or named preset and returns the resolved postponement. It funnels
+// into the same Scheduler.ScheduleMessage path as PostponeMessage.
+// swagger:operation POST /message/{id}/snooze message snoozeMessage
+//
+// Snooze a message using a relative duration or named preset.
+//
+//	---
+//	produces: [application/json]
+//	security: [clientTokenAuthorizationHeader: [], clientTokenHeader: [], clientTokenQuery: [], basicAuth: []]
+//	parameters:
+//	- name: id
+//	  in: path
+//	  description: the message id
+//	  required: true
+//	  type: integer
+//	  format: int64
+//	- name: at
+//	  in: query
+//	  description: the RFC3339 time to postpone the message until
+//	  required: false
+//	  type: string
+//	- name: in
+//	  in: query
+//	  description: a Go duration (e.g. 90m, 2h30m) to postpone the message by
+//	  required: false
+//	  type: string
+//	- name: preset
+//	  in: query
+//	  description: a named preset (see GET /snooze/presets) to postpone the message until
+//	  required: false
+//	  type: string
+//	responses:
+//	  200:
+//	    description: Ok
+//	  400:
+//	    description: Bad Request
+//	    schema:
+//	        $ref: "#/definitions/Error"
+//	  401:
+//	    description: Unauthorized
+//	    schema:
+//	        $ref: "#/definitions/Error"
+//	  403:
+//	    description: Forbidden
+//	    schema:
+//	        $ref: "#/definitions/Error"
+//	  404:
+//	    description: Not Found
+//	    schema:
+//	        $ref: "#/definitions/Error"
+func (a *MessageAPI) SnoozeMessage(ctx *gin.Context) {
+	postponedAt, err := a.resolveSnoozeTime(ctx)
+	if err != nil {
+		ctx.AbortWithError(400, err)
+		return
+	}
+	withID(ctx, "id", func(id uint) {
+		a.withOwnedMessage(ctx, id, func(msg *model.Message) {
+			a.Scheduler.DeleteMessageSchedule(msg)
+			a.Scheduler.ScheduleMessage(msg.ID, *postponedAt)
+			if success := successOrAbort(ctx, 500, a.DB.UpdateMessagePostponement(id, postponedAt)); !success {
+				return
+			}
+			ctx.JSON(200, gin.H{"postponed_at": postponedAt})
+		})
+	})
+}
+
+// GetSnoozePresets returns the named snooze presets resolved to absolute
+// timestamps in the authenticated user's timezone, so clients can render a
+// snooze menu without duplicating the resolution logic.
+// swagger:operation GET /snooze/presets message getSnoozePresets
+//
+// Return the available snooze presets resolved to absolute timestamps.
+//
+//	---
+//	produces: [application/json]
+//	security: [clientTokenAuthorizationHeader: [], clientTokenHeader: [], clientTokenQuery: [], basicAuth: []]
+//	responses:
+//	  200:
+//	    description: Ok
+//	  401:
+//	    description: Unauthorized
+//	    schema:
+//	        $ref: "#/definitions/Error"
+func (a *MessageAPI) GetSnoozePresets(ctx *gin.Context) {
+	loc, err := a.userTimezone(ctx)
+	if success := successOrAbort(ctx, 500, err); !success {
+		return
+	}
+	ctx.JSON(200, resolvePresets(timeNow(), loc))
+}
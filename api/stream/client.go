@@ -0,0 +1,177 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/gotify/server/v2/model"
+)
+
+// wsClient is a streamClient backed by a WebSocket connection.
+type wsClient struct {
+	conn       *websocket.Conn
+	writeWait  time.Duration
+	pingPeriod time.Duration
+	outbox     chan *model.MessageExternal
+	closed     chan struct{}
+}
+
+func newWSClient(conn *websocket.Conn, pingPeriod, writeWait time.Duration) *wsClient {
+	c := &wsClient{
+		conn:       conn,
+		writeWait:  writeWait,
+		pingPeriod: pingPeriod,
+		outbox:     make(chan *model.MessageExternal, 16),
+		closed:     make(chan struct{}),
+	}
+	go c.writeLoop()
+	return c
+}
+
+// send implements streamClient.
+func (c *wsClient) send(message *model.MessageExternal) {
+	select {
+	case c.outbox <- message:
+	case <-c.closed:
+	}
+}
+
+// close implements streamClient.
+func (c *wsClient) close() {
+	select {
+	case <-c.closed:
+	default:
+		close(c.closed)
+		c.conn.Close()
+	}
+}
+
+// readLoop discards incoming messages (the client never sends anything
+// meaningful) and blocks until the connection is closed, so Handle can defer
+// unregistering until the client actually disconnects.
+func (c *wsClient) readLoop() {
+	defer c.close()
+	for {
+		if _, _, err := c.conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+func (c *wsClient) writeLoop() {
+	ticker := time.NewTicker(c.pingPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case message := <-c.outbox:
+			c.conn.SetWriteDeadline(time.Now().Add(c.writeWait))
+			if err := c.conn.WriteJSON(message); err != nil {
+				c.close()
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(c.writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				c.close()
+				return
+			}
+		case <-c.closed:
+			return
+		}
+	}
+}
+
+// sseClient is a streamClient backed by a Server-Sent-Events response.
+type sseClient struct {
+	w          http.ResponseWriter
+	flusher    http.Flusher
+	pingPeriod time.Duration
+	messages   chan *model.MessageExternal
+	closed     chan struct{}
+}
+
+func newSSEClient(w http.ResponseWriter, flusher http.Flusher, pingPeriod time.Duration) *sseClient {
+	return &sseClient{
+		w:          w,
+		flusher:    flusher,
+		pingPeriod: pingPeriod,
+		messages:   make(chan *model.MessageExternal, 16),
+		closed:     make(chan struct{}),
+	}
+}
+
+// send implements streamClient.
+func (c *sseClient) send(message *model.MessageExternal) {
+	select {
+	case c.messages <- message:
+	case <-c.closed:
+	}
+}
+
+// close implements streamClient.
+func (c *sseClient) close() {
+	select {
+	case <-c.closed:
+	default:
+		close(c.closed)
+	}
+}
+
+// writeLoop writes queued messages as "id: <id>\ndata: <json>\n\n" SSE
+// frames (the id lets a reconnecting client send it back as Last-Event-ID)
+// until the request context is cancelled or the client is closed.
+func (c *sseClient) writeLoop(ctx context.Context) {
+	ticker := time.NewTicker(c.pingPeriod)
+	defer ticker.Stop()
+	defer c.close()
+	for {
+		select {
+		case message := <-c.messages:
+			payload, err := json.Marshal(message)
+			if err != nil {
+				continue
+			}
+			if _, err := c.w.Write([]byte("id: " + strconv.FormatUint(uint64(message.ID), 10) + "\ndata: ")); err != nil {
+				return
+			}
+			if _, err := c.w.Write(payload); err != nil {
+				return
+			}
+			if _, err := c.w.Write([]byte("\n\n")); err != nil {
+				return
+			}
+			c.flusher.Flush()
+		case <-ticker.C:
+			if _, err := c.w.Write([]byte(": ping\n\n")); err != nil {
+				return
+			}
+			c.flusher.Flush()
+		case <-ctx.Done():
+			return
+		case <-c.closed:
+			return
+		}
+	}
+}
+
+func toExternalMessage(msg *model.Message) *model.MessageExternal {
+	res := &model.MessageExternal{
+		ID:            msg.ID,
+		ApplicationID: msg.ApplicationID,
+		Message:       msg.Message,
+		Title:         msg.Title,
+		Priority:      &msg.Priority,
+		Date:          msg.Date,
+		PostponedAt:   msg.PostponedAt,
+		Schedule:      msg.Schedule,
+	}
+	if len(msg.Extras) != 0 {
+		res.Extras = make(map[string]interface{})
+		json.Unmarshal(msg.Extras, &res.Extras)
+	}
+	return res
+}
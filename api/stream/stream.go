@@ -0,0 +1,255 @@
+// Package stream fans new messages out to connected clients over WebSocket
+// or Server-Sent Events. It is deliberately transport-agnostic: callers only
+// ever see the Notify(userID, message) method, never which transports a
+// given client is using.
+package stream
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/gotify/server/v2/auth"
+	"github.com/gotify/server/v2/model"
+)
+
+// MessageDatabase is the minimal slice of database access stream needs to
+// replay missed messages to an SSE client that reconnects with a
+// Last-Event-ID. It's declared locally (rather than imported from the api
+// package) because api already imports scheduler, which imports stream --
+// importing api.MessageDatabase here would create a cycle.
+type MessageDatabase interface {
+	GetMessagesByUserAfterID(userID uint, afterID uint, limit int) ([]*model.Message, error)
+}
+
+// streamClient is anything Notify can push a message to, regardless of
+// transport.
+type streamClient interface {
+	send(message *model.MessageExternal)
+	close()
+}
+
+// API holds currently connected streamClients and fans new messages out to
+// them. It implements api.Notifier without importing the api package (see
+// MessageDatabase above).
+type API struct {
+	mu             sync.RWMutex
+	clients        map[uint][]streamClient
+	pingPeriod     time.Duration
+	writeWait      time.Duration
+	allowedOrigins []string
+	db             MessageDatabase
+}
+
+// New creates an API. db is used to replay messages a reconnecting SSE
+// client missed via Last-Event-ID; it may be nil if replay isn't needed
+// (e.g. in tests).
+func New(pingPeriod, writeWait time.Duration, allowedOrigins []string, db MessageDatabase) *API {
+	return &API{
+		clients:        make(map[uint][]streamClient),
+		pingPeriod:     pingPeriod,
+		writeWait:      writeWait,
+		allowedOrigins: allowedOrigins,
+		db:             db,
+	}
+}
+
+// Notify fans message out to every client (WebSocket or SSE) registered for
+// userID.
+func (a *API) Notify(userID uint, message *model.MessageExternal) {
+	a.mu.RLock()
+	clients := a.clients[userID]
+	a.mu.RUnlock()
+	for _, client := range clients {
+		client.send(message)
+	}
+}
+
+func (a *API) register(userID uint, client streamClient) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.clients[userID] = append(a.clients[userID], client)
+}
+
+func (a *API) unregister(userID uint, client streamClient) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	clients := a.clients[userID]
+	for i, c := range clients {
+		if c == client {
+			a.clients[userID] = append(clients[:i], clients[i+1:]...)
+			break
+		}
+	}
+	if len(a.clients[userID]) == 0 {
+		delete(a.clients, userID)
+	}
+}
+
+// isOriginAllowed reports whether origin is in allowedOrigins, or whether no
+// allow-list was configured at all (meaning every origin is allowed).
+func (a *API) isOriginAllowed(origin string) bool {
+	if len(a.allowedOrigins) == 0 {
+		return true
+	}
+	for _, allowed := range a.allowedOrigins {
+		if allowed == origin {
+			return true
+		}
+	}
+	return false
+}
+
+// replayLimit bounds how many missed messages are replayed to a
+// reconnecting SSE client, so a very stale Last-Event-ID can't trigger an
+// unbounded query.
+const replayLimit = 200
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+// Handle upgrades the request to a WebSocket connection and streams messages
+// for the authenticated user until the client disconnects.
+// swagger:operation GET /stream message streamMessages
+//
+// Websocket stream for newly created messages.
+//
+//	---
+//	security: [clientTokenAuthorizationHeader: [], clientTokenHeader: [], clientTokenQuery: [], basicAuth: []]
+//	responses:
+//	  101:
+//	    description: Switching Protocols
+//	  400:
+//	    description: Bad Request
+//	    schema:
+//	        $ref: "#/definitions/Error"
+//	  401:
+//	    description: Unauthorized
+//	    schema:
+//	        $ref: "#/definitions/Error"
+func (a *API) Handle(ctx *gin.Context) {
+	origin := ctx.Request.Header.Get("Origin")
+	if origin != "" && !a.isOriginAllowed(origin) {
+		ctx.AbortWithError(403, fmt.Errorf("origin %q is not allowed", origin))
+		return
+	}
+
+	conn, err := upgrader.Upgrade(ctx.Writer, ctx.Request, nil)
+	if err != nil {
+		return
+	}
+
+	userID := auth.GetUserID(ctx)
+	client := newWSClient(conn, a.pingPeriod, a.writeWait)
+	a.register(userID, client)
+	defer a.unregister(userID, client)
+
+	client.readLoop()
+}
+
+// HandleSSE streams messages for the authenticated user as Server-Sent
+// Events. A client reconnecting after a dropped connection can send a
+// Last-Event-ID header (the id of the last message it saw) to have missed
+// messages replayed before the stream resumes live.
+// swagger:operation GET /stream/sse message streamMessagesSSE
+//
+// Server-Sent-Events stream for newly created messages.
+//
+//	---
+//	produces: [text/event-stream]
+//	security: [clientTokenAuthorizationHeader: [], clientTokenHeader: [], clientTokenQuery: [], basicAuth: []]
+//	parameters:
+//	- name: Last-Event-ID
+//	  in: header
+//	  description: id of the last message the client saw; used to replay messages missed while disconnected
+//	  required: false
+//	  type: string
+//	responses:
+//	  200:
+//	    description: Ok
+//	  400:
+//	    description: Bad Request
+//	    schema:
+//	        $ref: "#/definitions/Error"
+//	  401:
+//	    description: Unauthorized
+//	    schema:
+//	        $ref: "#/definitions/Error"
+//	  403:
+//	    description: Forbidden
+//	    schema:
+//	        $ref: "#/definitions/Error"
+func (a *API) HandleSSE(ctx *gin.Context) {
+	origin := ctx.Request.Header.Get("Origin")
+	if origin != "" && !a.isOriginAllowed(origin) {
+		ctx.AbortWithError(403, fmt.Errorf("origin %q is not allowed", origin))
+		return
+	}
+
+	flusher, ok := ctx.Writer.(http.Flusher)
+	if !ok {
+		ctx.AbortWithError(500, fmt.Errorf("streaming unsupported"))
+		return
+	}
+
+	userID := auth.GetUserID(ctx)
+
+	ctx.Writer.Header().Set("Content-Type", "text/event-stream")
+	ctx.Writer.Header().Set("Cache-Control", "no-cache")
+	ctx.Writer.Header().Set("Connection", "keep-alive")
+	if origin != "" {
+		ctx.Writer.Header().Set("Access-Control-Allow-Origin", origin)
+	}
+	ctx.Writer.WriteHeader(200)
+	flusher.Flush()
+
+	client := newSSEClient(ctx.Writer, flusher, a.pingPeriod)
+	a.register(userID, client)
+	defer a.unregister(userID, client)
+
+	// Start draining before replaying: replayMissed can queue up to
+	// replayLimit messages, more than the client's outbox can buffer, and
+	// would block forever with nothing yet reading from it.
+	go client.writeLoop(ctx.Request.Context())
+
+	a.replayMissed(ctx, userID, client)
+
+	<-client.closed
+}
+
+// replayMissed resends messages the client missed while disconnected, using
+// the Last-Event-ID header as a lower-bound cursor. At most replayLimit
+// messages are replayed, oldest first; when the limit is reached, any newer
+// missed messages beyond it are not replayed, and this is logged rather than
+// silently lost without a trace.
+func (a *API) replayMissed(ctx *gin.Context, userID uint, client *sseClient) {
+	if a.db == nil {
+		return
+	}
+	lastEventID := ctx.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		return
+	}
+	since, err := strconv.ParseUint(lastEventID, 10, 64)
+	if err != nil {
+		return
+	}
+
+	missed, err := a.db.GetMessagesByUserAfterID(userID, uint(since), replayLimit)
+	if err != nil {
+		fmt.Println("stream: error replaying missed messages for user ", userID, err)
+		return
+	}
+	if len(missed) == replayLimit {
+		fmt.Println("stream: replay capped at", replayLimit, "messages for user", userID, "- newer missed messages may have been dropped")
+	}
+	for _, message := range missed {
+		client.send(toExternalMessage(message))
+	}
+}
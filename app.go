@@ -50,7 +50,7 @@ func main() {
 	}
 	defer db.Close()
 
-	streamHandler := stream.New(time.Duration(conf.Server.Stream.PingPeriodSeconds)*time.Second, 15*time.Second, conf.Server.Stream.AllowedOrigins)
+	streamHandler := stream.New(time.Duration(conf.Server.Stream.PingPeriodSeconds)*time.Second, 15*time.Second, conf.Server.Stream.AllowedOrigins, db)
 
 	jobScheduler, schedulerCloseable := scheduler.Init(db, streamHandler)
 	defer schedulerCloseable()
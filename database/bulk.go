@@ -0,0 +1,72 @@
+package database
+
+import (
+	"time"
+
+	"github.com/gotify/server/v2/model"
+	"gorm.io/gorm"
+)
+
+// GetMessagesByIDs returns the messages among ids that belong to one of the
+// user's applications.
+func (d *GormDatabase) GetMessagesByIDs(userID uint, ids []uint) ([]*model.Message, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var messages []*model.Message
+	err := d.DB.Joins("JOIN applications ON applications.id = messages.application_id").
+		Where("applications.user_id = ? AND messages.id IN ?", userID, ids).
+		Find(&messages).Error
+	return messages, err
+}
+
+// DeleteMessagesByIDs deletes the messages among ids that belong to one of
+// the user's applications and returns the ids that were actually deleted.
+func (d *GormDatabase) DeleteMessagesByIDs(userID uint, ids []uint) ([]uint, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var deleted []uint
+	err := d.DB.Transaction(func(tx *gorm.DB) error {
+		var owned []*model.Message
+		if err := tx.Joins("JOIN applications ON applications.id = messages.application_id").
+			Where("applications.user_id = ? AND messages.id IN ?", userID, ids).
+			Find(&owned).Error; err != nil {
+			return err
+		}
+		for _, msg := range owned {
+			deleted = append(deleted, msg.ID)
+		}
+		if len(deleted) == 0 {
+			return nil
+		}
+		return tx.Where("id IN ?", deleted).Delete(&model.Message{}).Error
+	})
+	return deleted, err
+}
+
+// UpdateMessagesPostponement sets or clears postponed_at for the messages
+// among ids that belong to one of the user's applications, returning the ids
+// that were actually updated.
+func (d *GormDatabase) UpdateMessagesPostponement(userID uint, ids []uint, postponedAt *time.Time) ([]uint, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var updated []uint
+	err := d.DB.Transaction(func(tx *gorm.DB) error {
+		var owned []*model.Message
+		if err := tx.Joins("JOIN applications ON applications.id = messages.application_id").
+			Where("applications.user_id = ? AND messages.id IN ?", userID, ids).
+			Find(&owned).Error; err != nil {
+			return err
+		}
+		for _, msg := range owned {
+			updated = append(updated, msg.ID)
+		}
+		if len(updated) == 0 {
+			return nil
+		}
+		return tx.Model(&model.Message{}).Where("id IN ?", updated).Update("postponed_at", postponedAt).Error
+	})
+	return updated, err
+}
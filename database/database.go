@@ -0,0 +1,81 @@
+package database
+
+import (
+	"github.com/gotify/server/v2/model"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// GormDatabase is the logic layer for accessing the data layer (gorm). It
+// backs every MessageDatabase/JobStore used by the api and scheduler
+// packages in this checkout; application/client/user management beyond
+// GetUserByID and GetApplicationsByUser is out of scope here.
+type GormDatabase struct {
+	DB *gorm.DB
+}
+
+// New creates a new GormDatabase, automigrates the schema when autoMigrate is
+// set, and creates a default admin user if no user with that name exists yet.
+func New(dialect, connection, defaultUserName, defaultUserPass string, passStrength int, autoMigrate bool) (*GormDatabase, error) {
+	db, err := openDB(dialect, connection)
+	if err != nil {
+		return nil, err
+	}
+
+	gormDb := &GormDatabase{DB: db}
+
+	if autoMigrate {
+		if err := gormDb.migrate(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := gormDb.createDefaultUserIfNotExists(defaultUserName, defaultUserPass, passStrength); err != nil {
+		return nil, err
+	}
+
+	return gormDb, nil
+}
+
+func openDB(dialect, connection string) (*gorm.DB, error) {
+	switch dialect {
+	case "postgres":
+		return gorm.Open(postgres.Open(connection), &gorm.Config{})
+	default:
+		return gorm.Open(sqlite.Open(connection), &gorm.Config{})
+	}
+}
+
+func (d *GormDatabase) migrate() error {
+	return d.DB.AutoMigrate(
+		&model.User{},
+		&model.Application{},
+		&model.Message{},
+	)
+}
+
+func (d *GormDatabase) createDefaultUserIfNotExists(name, pass string, passStrength int) error {
+	var count int64
+	if err := d.DB.Model(&model.User{}).Where("name = ?", name).Count(&count).Error; err != nil {
+		return err
+	}
+	if count != 0 {
+		return nil
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(pass), passStrength)
+	if err != nil {
+		return err
+	}
+
+	return d.DB.Create(&model.User{Name: name, Pass: hash, Admin: true, Timezone: "UTC"}).Error
+}
+
+// Close closes the underlying database connection.
+func (d *GormDatabase) Close() {
+	if sqlDB, err := d.DB.DB(); err == nil {
+		sqlDB.Close()
+	}
+}
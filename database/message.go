@@ -0,0 +1,131 @@
+package database
+
+import (
+	"time"
+
+	"github.com/gotify/server/v2/model"
+	"gorm.io/gorm"
+)
+
+// GetMessagesByApplicationSince returns limit messages for an application,
+// with an id less than since (0 meaning no upper bound), optionally filtered
+// by postponement state ("true"/"false").
+func (d *GormDatabase) GetMessagesByApplicationSince(appID uint, limit int, since uint, postponed *string) ([]*model.Message, error) {
+	var messages []*model.Message
+	query := d.DB.Where("application_id = ?", appID)
+	query = withSince(query, since)
+	query = withPostponed(query, postponed)
+	err := query.Order("id desc").Limit(limit).Find(&messages).Error
+	return messages, err
+}
+
+// GetMessagesByUserSince returns limit messages across all of a user's
+// applications, with an id less than since (0 meaning no upper bound),
+// optionally filtered by postponement state.
+func (d *GormDatabase) GetMessagesByUserSince(userID uint, limit int, since uint, postponed *string) ([]*model.Message, error) {
+	var messages []*model.Message
+	query := d.DB.Joins("JOIN applications ON applications.id = messages.application_id").
+		Where("applications.user_id = ?", userID)
+	query = withSince(query, since)
+	query = withPostponed(query, postponed)
+	err := query.Order("messages.id desc").Limit(limit).Find(&messages).Error
+	return messages, err
+}
+
+func withSince(query *gorm.DB, since uint) *gorm.DB {
+	if since != 0 {
+		return query.Where("messages.id < ?", since)
+	}
+	return query
+}
+
+func withPostponed(query *gorm.DB, postponed *string) *gorm.DB {
+	if postponed == nil {
+		return query
+	}
+	if *postponed == "true" {
+		return query.Where("postponed_at IS NOT NULL")
+	}
+	return query.Where("postponed_at IS NULL")
+}
+
+// GetApplicationByID returns an application by id, or nil if it doesn't
+// exist.
+func (d *GormDatabase) GetApplicationByID(id uint) (*model.Application, error) {
+	app := new(model.Application)
+	err := d.DB.Where("id = ?", id).First(app).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	return app, err
+}
+
+// GetApplicationByToken returns an application by its token, or nil if it
+// doesn't exist.
+func (d *GormDatabase) GetApplicationByToken(token string) (*model.Application, error) {
+	app := new(model.Application)
+	err := d.DB.Where("token = ?", token).First(app).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	return app, err
+}
+
+// GetApplicationsByUser returns every application belonging to a user.
+func (d *GormDatabase) GetApplicationsByUser(userID uint) ([]*model.Application, error) {
+	var apps []*model.Application
+	err := d.DB.Where("user_id = ?", userID).Find(&apps).Error
+	return apps, err
+}
+
+// GetMessageByID returns a message by id, or nil if it doesn't exist.
+func (d *GormDatabase) GetMessageByID(id uint) (*model.Message, error) {
+	msg := new(model.Message)
+	err := d.DB.Where("id = ?", id).First(msg).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	return msg, err
+}
+
+// DeleteMessageByID deletes a message by id.
+func (d *GormDatabase) DeleteMessageByID(id uint) error {
+	return d.DB.Where("id = ?", id).Delete(&model.Message{}).Error
+}
+
+// DeleteMessagesByUser deletes every message belonging to any of a user's
+// applications.
+func (d *GormDatabase) DeleteMessagesByUser(userID uint) error {
+	apps, err := d.GetApplicationsByUser(userID)
+	if err != nil {
+		return err
+	}
+	for _, app := range apps {
+		if err := d.DeleteMessagesByApplication(app.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteMessagesByApplication deletes every message belonging to an
+// application.
+func (d *GormDatabase) DeleteMessagesByApplication(applicationID uint) error {
+	return d.DB.Where("application_id = ?", applicationID).Delete(&model.Message{}).Error
+}
+
+// CreateMessage persists a new message.
+func (d *GormDatabase) CreateMessage(message *model.Message) error {
+	return d.DB.Create(message).Error
+}
+
+// UpdateMessagePostponement sets or clears a message's postponed_at.
+func (d *GormDatabase) UpdateMessagePostponement(id uint, postponedAt *time.Time) error {
+	return d.DB.Model(&model.Message{}).Where("id = ?", id).Update("postponed_at", postponedAt).Error
+}
+
+// UpdateMessageSchedule sets or clears a message template's recurring
+// schedule.
+func (d *GormDatabase) UpdateMessageSchedule(id uint, schedule *string) error {
+	return d.DB.Model(&model.Message{}).Where("id = ?", id).Update("schedule", schedule).Error
+}
@@ -0,0 +1,18 @@
+package database
+
+import "github.com/gotify/server/v2/model"
+
+// GetMessagesByUserAfterID returns up to limit messages belonging to a
+// user's applications with an id greater than afterID, ordered oldest-first.
+// Unlike GetMessagesByUserSince (an upper-bound "before this id" cursor used
+// for backward pagination), this is a lower-bound "since I last saw this id"
+// cursor, which is what replaying missed stream messages needs.
+func (d *GormDatabase) GetMessagesByUserAfterID(userID uint, afterID uint, limit int) ([]*model.Message, error) {
+	var messages []*model.Message
+	err := d.DB.Joins("JOIN applications ON applications.id = messages.application_id").
+		Where("applications.user_id = ? AND messages.id > ?", userID, afterID).
+		Order("messages.id asc").
+		Limit(limit).
+		Find(&messages).Error
+	return messages, err
+}
@@ -0,0 +1,16 @@
+package database
+
+import (
+	"github.com/gotify/server/v2/model"
+	"gorm.io/gorm"
+)
+
+// GetUserByID returns a user by id, or nil if it doesn't exist.
+func (d *GormDatabase) GetUserByID(id uint) (*model.User, error) {
+	user := new(model.User)
+	err := d.DB.Where("id = ?", id).First(user).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	return user, err
+}
@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// Application is an application that can send messages for a user.
+type Application struct {
+	ID              uint   `gorm:"primary_key;auto_increment"`
+	Token           string `gorm:"index"`
+	UserID          uint
+	Name            string
+	Description     string
+	Internal        bool
+	Image           string
+	DefaultPriority int
+	LastUsed        *time.Time
+}
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Message is a message sent by an application to a user, as stored in the
+// database.
+type Message struct {
+	ID            uint   `gorm:"primary_key;auto_increment"`
+	ApplicationID uint   `gorm:"index"`
+	Message       string `gorm:"type:text"`
+	Title         string
+	Priority      int
+	Extras        json.RawMessage `gorm:"type:text"`
+	Date          time.Time       `gorm:"index"`
+	PostponedAt   *time.Time      `gorm:"index"`
+	// Schedule is either a cron expression or an ISO-8601 duration for
+	// messages that act as a recurring template; nil for one-shot messages.
+	Schedule *string `gorm:"index"`
+}
+
+// MessageExternal is the public JSON representation of a Message.
+//
+// swagger:model Message
+type MessageExternal struct {
+	ID            uint                   `json:"id"`
+	ApplicationID uint                   `json:"appid"`
+	Message       string                 `json:"message"`
+	Title         string                 `json:"title"`
+	Priority      *int                   `json:"priority,omitempty"`
+	Extras        map[string]interface{} `json:"extras,omitempty"`
+	Date          time.Time              `json:"date"`
+	PostponedAt   *time.Time             `json:"postponed_at,omitempty"`
+	Schedule      *string                `json:"schedule,omitempty"`
+}
+
+// Paging holds paging information for a PagedMessages response.
+//
+// swagger:model Paging
+type Paging struct {
+	Since uint   `json:"since,omitempty"`
+	Size  int    `json:"size"`
+	Limit int    `json:"limit"`
+	Next  string `json:"next,omitempty"`
+}
+
+// PagedMessages holds a page of messages and the corresponding paging info.
+//
+// swagger:model PagedMessages
+type PagedMessages struct {
+	Paging   Paging             `json:"paging"`
+	Messages []*MessageExternal `json:"messages"`
+}
@@ -0,0 +1,12 @@
+package model
+
+// User is a user of gotify.
+type User struct {
+	ID    uint   `gorm:"primary_key;auto_increment"`
+	Name  string `gorm:"type:text;unique_index"`
+	Pass  []byte
+	Admin bool
+	// Timezone is an IANA time zone name used to resolve relative snooze
+	// presets; defaults to UTC for users that never set one.
+	Timezone string `gorm:"type:text;default:'UTC'"`
+}
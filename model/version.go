@@ -0,0 +1,10 @@
+package model
+
+// VersionInfo shows information about the build.
+//
+// swagger:model VersionInfo
+type VersionInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"buildDate"`
+}
@@ -0,0 +1,54 @@
+// Package router wires HTTP routes to their handlers. This checkout only
+// registers the message-related surface touched by this backlog; user/
+// application/client management, the static web UI and health checks live
+// elsewhere in the full server and are out of scope here.
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gotify/server/v2/api"
+	"github.com/gotify/server/v2/api/stream"
+	"github.com/gotify/server/v2/auth"
+	"github.com/gotify/server/v2/config"
+	"github.com/gotify/server/v2/database"
+	"github.com/gotify/server/v2/model"
+	"github.com/gotify/server/v2/scheduler"
+)
+
+// Create builds the gin engine and registers every route, returning a
+// closeable for any resources the router itself owns.
+func Create(db *database.GormDatabase, vInfo *model.VersionInfo, conf *config.Configuration, streamHandler *stream.API, jobScheduler *scheduler.Scheduler) (*gin.Engine, func() error) {
+	engine := gin.New()
+
+	messageAPI := &api.MessageAPI{DB: db, Notifier: streamHandler, Scheduler: jobScheduler}
+
+	clientAuth := auth.RequireClient()
+	appAuth := auth.RequireApplicationToken()
+
+	message := engine.Group("/message")
+	{
+		message.GET("", clientAuth, messageAPI.GetMessages)
+		message.DELETE("", clientAuth, messageAPI.DeleteMessages)
+		message.POST("", appAuth, messageAPI.CreateMessage)
+		message.POST("/bulk", clientAuth, messageAPI.BulkMessage)
+		message.DELETE("/:id", clientAuth, messageAPI.DeleteMessage)
+		message.PUT("/:id/schedule", clientAuth, messageAPI.UpdateMessageSchedule)
+		message.DELETE("/:id/schedule", clientAuth, messageAPI.DeleteMessageSchedule)
+		message.POST("/:id/postpone", clientAuth, messageAPI.PostponeMessage)
+		message.DELETE("/:id/postpone", clientAuth, messageAPI.DeleteMessagePostponement)
+		message.POST("/:id/snooze", clientAuth, messageAPI.SnoozeMessage)
+	}
+
+	engine.GET("/snooze/presets", clientAuth, messageAPI.GetSnoozePresets)
+
+	application := engine.Group("/application")
+	{
+		application.GET("/:id/message", clientAuth, messageAPI.GetMessagesWithApplication)
+		application.DELETE("/:id/message", clientAuth, messageAPI.DeleteMessageWithApplication)
+	}
+
+	engine.GET("/stream", clientAuth, streamHandler.Handle)
+	engine.GET("/stream/sse", clientAuth, streamHandler.HandleSSE)
+
+	return engine, func() error { return nil }
+}
@@ -0,0 +1,108 @@
+package scheduler
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gotify/server/v2/database"
+	"gorm.io/gorm"
+)
+
+// JobKind distinguishes a one-shot postponement from a recurring schedule.
+type JobKind string
+
+const (
+	KindOneTime JobKind = "one-shot"
+	KindCron    JobKind = "cron"
+)
+
+// MisfirePolicy controls what happens to a scheduled job whose fire time
+// elapsed while the server was down.
+type MisfirePolicy string
+
+const (
+	// MisfireFireImmediately fires the job as soon as the server comes back up.
+	MisfireFireImmediately MisfirePolicy = "fire_immediately"
+	// MisfireFireOnceThenResume fires the missed occurrence once and then
+	// resumes the normal schedule from now on.
+	MisfireFireOnceThenResume MisfirePolicy = "fire_once_then_resume"
+	// MisfireSkip drops the missed occurrence and resumes the normal
+	// schedule from now on.
+	MisfireSkip MisfirePolicy = "skip"
+)
+
+// DefaultMisfirePolicy is applied to jobs scheduled through ScheduleMessage
+// and ScheduleRecurring.
+const DefaultMisfirePolicy = MisfireFireImmediately
+
+// ScheduledJob is the durable record of a one-shot or recurring schedule. It
+// survives restarts so Init can re-register pending jobs with gocron instead
+// of silently dropping postponements that elapsed while the server was down.
+type ScheduledJob struct {
+	ID            uuid.UUID `gorm:"type:uuid;primary_key"`
+	MessageID     uint      `gorm:"index"`
+	UserID        uint      `gorm:"index"`
+	Kind          JobKind
+	Schedule      string // cron expression or ISO-8601 duration; empty for one-shot jobs
+	NextFireAt    time.Time
+	LastFiredAt   *time.Time
+	MisfirePolicy MisfirePolicy
+}
+
+// JobStore persists ScheduledJob rows.
+type JobStore interface {
+	Create(job *ScheduledJob) error
+	Update(job *ScheduledJob) error
+	DeleteByMessageID(messageID uint) error
+	ListAll() ([]*ScheduledJob, error)
+	ListByUser(userID uint) ([]*ScheduledJob, error)
+	// GetByMessageID returns the persisted job for a message, or nil if none
+	// exists.
+	GetByMessageID(messageID uint) (*ScheduledJob, error)
+}
+
+type gormJobStore struct {
+	db *database.GormDatabase
+}
+
+// NewJobStore returns a JobStore backed by the given GormDatabase.
+func NewJobStore(db *database.GormDatabase) JobStore {
+	return &gormJobStore{db: db}
+}
+
+func (s *gormJobStore) Create(job *ScheduledJob) error {
+	return s.db.DB.Create(job).Error
+}
+
+func (s *gormJobStore) Update(job *ScheduledJob) error {
+	return s.db.DB.Save(job).Error
+}
+
+func (s *gormJobStore) DeleteByMessageID(messageID uint) error {
+	return s.db.DB.Where("message_id = ?", messageID).Delete(&ScheduledJob{}).Error
+}
+
+func (s *gormJobStore) ListAll() ([]*ScheduledJob, error) {
+	var jobs []*ScheduledJob
+	err := s.db.DB.Find(&jobs).Error
+	return jobs, err
+}
+
+func (s *gormJobStore) ListByUser(userID uint) ([]*ScheduledJob, error) {
+	var jobs []*ScheduledJob
+	err := s.db.DB.Where("user_id = ?", userID).Find(&jobs).Error
+	return jobs, err
+}
+
+func (s *gormJobStore) GetByMessageID(messageID uint) (*ScheduledJob, error) {
+	var job ScheduledJob
+	err := s.db.DB.Where("message_id = ?", messageID).First(&job).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
@@ -2,12 +2,17 @@ package scheduler
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/go-co-op/gocron/v2"
 	"github.com/google/uuid"
 	"github.com/gotify/server/v2/api/stream"
 	"github.com/gotify/server/v2/database"
 	"github.com/gotify/server/v2/model"
+	"github.com/robfig/cron/v3"
+	"regexp"
+	"strconv"
+	"sync"
 	"time"
 )
 
@@ -15,12 +20,20 @@ type Scheduler struct {
 	db        *database.GormDatabase
 	scheduler gocron.Scheduler
 	api       *stream.API
+	store     JobStore
+
+	mu   sync.RWMutex
+	jobs map[uint]uuid.UUID // message id -> gocron job id
 }
 
-// map message id -> job id
-var jobs = make(map[uint]uuid.UUID)
+// ErrInvalidSchedule is returned when a schedule string is neither a valid
+// cron expression nor a valid ISO-8601 duration.
+var ErrInvalidSchedule = errors.New("schedule must be a cron expression or an ISO-8601 duration")
+
+// timeNow is overridden in tests.
+var timeNow = time.Now
 
-func Init(database *database.GormDatabase, api *stream.API) (Scheduler, func() error) {
+func Init(db *database.GormDatabase, api *stream.API) (*Scheduler, func() error) {
 	cronScheduler, err := gocron.NewScheduler()
 	if err != nil {
 		fmt.Println("scheduler error: ", err)
@@ -28,25 +41,186 @@ func Init(database *database.GormDatabase, api *stream.API) (Scheduler, func() e
 	}
 
 	cronScheduler.Start()
-	scheduler := Scheduler{
-		db:        database,
+	scheduler := &Scheduler{
+		db:        db,
 		api:       api,
 		scheduler: cronScheduler,
+		store:     NewJobStore(db),
+		jobs:      make(map[uint]uuid.UUID),
 	}
-	scheduler.scheduleAll()
+	if err := db.DB.AutoMigrate(&ScheduledJob{}); err != nil {
+		fmt.Println("scheduler: error migrating scheduled jobs table", err)
+	}
+	scheduler.reconcile()
 
 	return scheduler, cronScheduler.Shutdown
 }
 
-func (s Scheduler) scheduleAll() {
-	var messages []*model.Message
-	s.db.DB.Where("postponed_at >= ?", time.Now()).Find(&messages)
-	for _, message := range messages {
-		s.ScheduleMessage(message.ID, *message.PostponedAt)
+// reconcile walks the durable scheduled_jobs table on boot, applying each
+// job's misfire policy to postponements/fires that elapsed while the server
+// was down, before re-registering everything else with gocron.
+func (s *Scheduler) reconcile() {
+	jobsList, err := s.store.ListAll()
+	if err != nil {
+		fmt.Println("scheduler: error loading scheduled jobs", err)
+		return
+	}
+
+	onTime, missed := classifyJobs(jobsList, timeNow())
+	for _, job := range onTime {
+		s.registerJob(job)
+	}
+	for _, job := range missed {
+		s.reconcileMissed(job)
+	}
+}
+
+// classifyJobs splits scheduled jobs into those whose next fire time hasn't
+// elapsed yet and those that were missed while the server was down.
+func classifyJobs(jobsList []*ScheduledJob, now time.Time) (onTime, missed []*ScheduledJob) {
+	for _, job := range jobsList {
+		if job.NextFireAt.Before(now) {
+			missed = append(missed, job)
+		} else {
+			onTime = append(onTime, job)
+		}
+	}
+	return
+}
+
+// reconcileMissed applies a job's misfire policy to a fire time that elapsed
+// while the server was down:
+//   - fire_immediately fires the missed occurrence synchronously, before the
+//     scheduler resumes its normal loop.
+//   - fire_once_then_resume defers the catch-up fire by a second so it runs
+//     after the scheduler has resumed, then keeps the normal cadence for
+//     recurring jobs.
+//   - skip drops the missed occurrence; one-shot jobs are discarded entirely,
+//     recurring jobs simply resume on their normal cadence.
+func (s *Scheduler) reconcileMissed(job *ScheduledJob) {
+	switch job.MisfirePolicy {
+	case MisfireSkip:
+		if job.Kind == KindOneTime {
+			if err := s.store.DeleteByMessageID(job.MessageID); err != nil {
+				fmt.Println("scheduler: error dropping skipped job for message ", job.MessageID, err)
+			}
+			return
+		}
+		s.registerJob(job)
+	case MisfireFireOnceThenResume:
+		s.scheduleCatchUp(job, time.Now().Add(time.Second))
+		if job.Kind == KindCron {
+			s.registerJob(job)
+		}
+	default: // MisfireFireImmediately
+		s.fireNow(job)
+		if job.Kind == KindCron {
+			s.registerJob(job)
+		}
+	}
+}
+
+// registerJob re-registers an already-persisted job with gocron, used on
+// boot for on-time jobs and, after misfire handling, for recurring jobs.
+func (s *Scheduler) registerJob(job *ScheduledJob) {
+	switch job.Kind {
+	case KindOneTime:
+		s.registerOneTime(job.MessageID, job.NextFireAt)
+	case KindCron:
+		if err := s.registerRecurring(job.MessageID, job.Schedule); err != nil {
+			fmt.Println("scheduler: error re-registering recurring message with id ", job.MessageID, err)
+		}
+	}
+}
+
+// fireNow fires a missed job immediately instead of waiting for its original
+// (now past) fire time.
+func (s *Scheduler) fireNow(job *ScheduledJob) {
+	switch job.Kind {
+	case KindOneTime:
+		var message model.Message
+		if err := s.db.DB.Where("id = ?", job.MessageID).First(&message).Error; err != nil {
+			fmt.Println("scheduler: error getting message with id ", job.MessageID, err)
+			return
+		}
+		s.fireOneTimeMessage(&message)
+	case KindCron:
+		s.fireRecurringTemplate(job.MessageID)
+		s.markFired(job.MessageID)
+	}
+}
+
+// fireOneTimeMessage finalizes a one-shot fire: it unregisters the schedule
+// (dropping the persisted job so it isn't re-classified as missed on the
+// next boot), clears the message's postponement, and notifies the owning
+// user.
+func (s *Scheduler) fireOneTimeMessage(message *model.Message) {
+	s.DeleteMessageSchedule(message)
+	if err := s.clearPostponement(message); err != nil {
+		fmt.Println("scheduler: error clearing postponed_at for message ", message.ID, err)
+	}
+	userID, err := s.userIDForApplication(message.ApplicationID)
+	if err != nil {
+		fmt.Println("scheduler: error resolving user for message ", message.ID, err)
+		return
 	}
+	s.api.Notify(userID, ToExternalMessage(message))
 }
 
-func (s Scheduler) ScheduleMessage(msgId uint, postponedAt time.Time) {
+// clearPostponement clears a fired message's postponed_at column, both in
+// the database and on the in-memory copy passed to Notify.
+func (s *Scheduler) clearPostponement(message *model.Message) error {
+	if err := s.db.UpdateMessagePostponement(message.ID, nil); err != nil {
+		return err
+	}
+	message.PostponedAt = nil
+	return nil
+}
+
+// scheduleCatchUp registers a one-time gocron job that fires a missed
+// occurrence shortly after boot, used by the fire_once_then_resume misfire
+// policy so the catch-up fire happens after the scheduler has resumed its
+// normal loop rather than blocking reconcile.
+func (s *Scheduler) scheduleCatchUp(job *ScheduledJob, at time.Time) {
+	msgId := job.MessageID
+	kind := job.Kind
+	_, err := s.scheduler.NewJob(
+		gocron.OneTimeJob(gocron.OneTimeJobStartDateTime(at)),
+		gocron.NewTask(func() {
+			switch kind {
+			case KindOneTime:
+				var message model.Message
+				if err := s.db.DB.Where("id = ?", msgId).First(&message).Error; err != nil {
+					fmt.Println("scheduler: error getting message with id ", msgId, err)
+					return
+				}
+				s.fireOneTimeMessage(&message)
+			case KindCron:
+				s.fireRecurringTemplate(msgId)
+				s.markFired(msgId)
+			}
+		}),
+	)
+	if err != nil {
+		fmt.Println("scheduler: error scheduling catch-up fire for message ", msgId, err)
+	}
+}
+
+// ScheduleMessage registers a one-shot job for a message and persists it
+// with the default misfire policy so it can be reconciled on the next boot
+// if the server goes down before it fires.
+func (s *Scheduler) ScheduleMessage(msgId uint, postponedAt time.Time) {
+	s.ScheduleMessageWithPolicy(msgId, postponedAt, DefaultMisfirePolicy)
+}
+
+// ScheduleMessageWithPolicy is like ScheduleMessage but lets the caller pick
+// the misfire policy applied if the server is down when postponedAt elapses.
+func (s *Scheduler) ScheduleMessageWithPolicy(msgId uint, postponedAt time.Time, policy MisfirePolicy) {
+	s.registerOneTime(msgId, postponedAt)
+	s.persistJob(msgId, KindOneTime, "", postponedAt, policy)
+}
+
+func (s *Scheduler) registerOneTime(msgId uint, postponedAt time.Time) {
 	job, err := s.scheduler.NewJob(
 		gocron.OneTimeJob(
 			gocron.OneTimeJobStartDateTime(postponedAt),
@@ -58,29 +232,213 @@ func (s Scheduler) ScheduleMessage(msgId uint, postponedAt time.Time) {
 				fmt.Println("Error getting message with id ", msgId, db.Error)
 				panic(db.Error)
 			}
-			userId := message.ApplicationID
-			// remove from the job list only, i want to keep track of the postponed date and time
-			s.DeleteMessageSchedule(&message)
-			s.api.Notify(userId, ToExternalMessage(&message))
+			s.fireOneTimeMessage(&message)
 		}),
 	)
 	if err != nil {
 		fmt.Println("Error scheduling message with id ", msgId, err)
 		return
 	}
-	jobs[msgId] = job.ID()
+	s.setJob(msgId, job.ID())
+}
+
+// ScheduleRecurring registers a recurring job for a message template with the
+// default misfire policy. schedule is either a cron expression (e.g.
+// "0 9 * * MON-FRI") or an ISO-8601 duration (e.g. "PT2H"). On every fire a
+// fresh model.Message is cloned from the template (preserving
+// priority/extras/title) and pushed via stream.API.Notify. The schedule is
+// persisted so it can be re-registered on the next boot.
+func (s *Scheduler) ScheduleRecurring(msgId uint, schedule string) error {
+	return s.ScheduleRecurringWithPolicy(msgId, schedule, DefaultMisfirePolicy)
+}
+
+// ScheduleRecurringWithPolicy is like ScheduleRecurring but lets the caller
+// pick the misfire policy applied to occurrences missed while the server is
+// down.
+func (s *Scheduler) ScheduleRecurringWithPolicy(msgId uint, schedule string, policy MisfirePolicy) error {
+	if err := s.registerRecurring(msgId, schedule); err != nil {
+		return err
+	}
+	nextFireAt, err := nextFireTime(schedule, timeNow())
+	if err != nil {
+		nextFireAt = timeNow()
+	}
+	s.persistJob(msgId, KindCron, schedule, nextFireAt, policy)
+	return nil
+}
+
+func (s *Scheduler) registerRecurring(msgId uint, schedule string) error {
+	definition, err := jobDefinitionForSchedule(schedule)
+	if err != nil {
+		return err
+	}
+
+	job, err := s.scheduler.NewJob(
+		definition,
+		gocron.NewTask(func() {
+			s.fireRecurringTemplate(msgId)
+			s.markFired(msgId)
+		}),
+	)
+	if err != nil {
+		fmt.Println("Error scheduling recurring message with id ", msgId, err)
+		return err
+	}
+	s.setJob(msgId, job.ID())
+	return nil
+}
+
+// fireRecurringTemplate clones the message template identified by msgId into
+// a fresh model.Message (preserving priority/extras/title) and notifies.
+func (s *Scheduler) fireRecurringTemplate(msgId uint) {
+	var template model.Message
+	if err := s.db.DB.Where("id = ?", msgId).First(&template).Error; err != nil {
+		fmt.Println("scheduler: error getting message template with id ", msgId, err)
+		return
+	}
+
+	fired := &model.Message{
+		ApplicationID: template.ApplicationID,
+		Message:       template.Message,
+		Title:         template.Title,
+		Priority:      template.Priority,
+		Extras:        template.Extras,
+		Date:          time.Now(),
+	}
+	if err := s.db.DB.Create(fired).Error; err != nil {
+		fmt.Println("scheduler: error creating message fired from recurring template ", msgId, err)
+		return
+	}
+	userID, err := s.userIDForApplication(fired.ApplicationID)
+	if err != nil {
+		fmt.Println("scheduler: error resolving user for message ", fired.ID, err)
+		return
+	}
+	s.api.Notify(userID, ToExternalMessage(fired))
+}
+
+// markFired records a recurring job's last fire time and advances its
+// NextFireAt so reconcile can tell whether a future occurrence was missed.
+func (s *Scheduler) markFired(msgId uint) {
+	job, err := s.store.GetByMessageID(msgId)
+	if err != nil || job == nil {
+		return
+	}
+	now := timeNow()
+	job.LastFiredAt = &now
+	if next, err := nextFireTime(job.Schedule, now); err == nil {
+		job.NextFireAt = next
+	}
+	if err := s.store.Update(job); err != nil {
+		fmt.Println("scheduler: error recording last fired time for message ", msgId, err)
+	}
+}
+
+// jobDefinitionForSchedule turns a cron expression or ISO-8601 duration into
+// a gocron job definition.
+func jobDefinitionForSchedule(schedule string) (gocron.JobDefinition, error) {
+	if duration, err := parseISO8601Duration(schedule); err == nil {
+		return gocron.DurationJob(duration), nil
+	}
+	if _, err := cron.ParseStandard(schedule); err != nil {
+		return nil, ErrInvalidSchedule
+	}
+	return gocron.CronJob(schedule, false), nil
+}
+
+// nextFireTime computes the next occurrence of schedule (a cron expression
+// or ISO-8601 duration) relative to from.
+func nextFireTime(schedule string, from time.Time) (time.Time, error) {
+	if duration, err := parseISO8601Duration(schedule); err == nil {
+		return from.Add(duration), nil
+	}
+	sched, err := cron.ParseStandard(schedule)
+	if err != nil {
+		return time.Time{}, ErrInvalidSchedule
+	}
+	return sched.Next(from), nil
 }
 
-func (s Scheduler) DeleteMessageSchedule(message *model.Message) {
-	jobId, ok := jobs[message.ID]
-	if !ok {
+// persistJob writes the durable record for a newly registered job. Errors are
+// logged rather than returned since the in-memory gocron job is already live
+// at this point; at worst a missed fire during downtime won't be reconciled.
+func (s *Scheduler) persistJob(msgId uint, kind JobKind, schedule string, nextFireAt time.Time, policy MisfirePolicy) {
+	userID, err := s.userIDForMessage(msgId)
+	if err != nil {
+		fmt.Println("scheduler: error resolving user for message ", msgId, err)
 		return
 	}
-	s.scheduler.RemoveJob(jobId)
-	delete(jobs, message.ID)
+	job := &ScheduledJob{
+		ID:            uuid.New(),
+		MessageID:     msgId,
+		UserID:        userID,
+		Kind:          kind,
+		Schedule:      schedule,
+		NextFireAt:    nextFireAt,
+		MisfirePolicy: policy,
+	}
+	if err := s.store.Create(job); err != nil {
+		fmt.Println("scheduler: error persisting scheduled job for message ", msgId, err)
+	}
 }
 
-func (s Scheduler) DeleteMessagesScheduleByApplication(appID uint) {
+func (s *Scheduler) userIDForMessage(msgId uint) (uint, error) {
+	var message model.Message
+	if err := s.db.DB.Where("id = ?", msgId).First(&message).Error; err != nil {
+		return 0, err
+	}
+	return s.userIDForApplication(message.ApplicationID)
+}
+
+// userIDForApplication resolves the owning user id for an application, for
+// callers that already have an application id in hand (e.g. from a message
+// they've already loaded) and want to avoid a redundant message lookup.
+func (s *Scheduler) userIDForApplication(appID uint) (uint, error) {
+	var app model.Application
+	if err := s.db.DB.Where("id = ?", appID).First(&app).Error; err != nil {
+		return 0, err
+	}
+	return app.UserID, nil
+}
+
+func (s *Scheduler) setJob(msgId uint, jobId uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[msgId] = jobId
+}
+
+func (s *Scheduler) DeleteMessageSchedule(message *model.Message) {
+	s.removeJob(message.ID)
+}
+
+// DeleteRecurringSchedule stops a recurring message template, leaving the
+// template row itself untouched.
+func (s *Scheduler) DeleteRecurringSchedule(msgId uint) {
+	s.removeJob(msgId)
+}
+
+// removeJob unregisters a job from gocron, if it's currently registered in
+// this process, and always deletes its persisted row. The persisted row must
+// be deleted unconditionally: a job fired via reconcile (fireNow/
+// scheduleCatchUp) never went through setJob in this process, so gating the
+// delete behind the in-memory lookup left its row behind to be re-classified
+// as missed and re-fired on every subsequent boot.
+func (s *Scheduler) removeJob(msgId uint) {
+	s.mu.Lock()
+	jobId, ok := s.jobs[msgId]
+	if ok {
+		delete(s.jobs, msgId)
+	}
+	s.mu.Unlock()
+	if ok {
+		s.scheduler.RemoveJob(jobId)
+	}
+	if err := s.store.DeleteByMessageID(msgId); err != nil {
+		fmt.Println("scheduler: error deleting persisted job for message ", msgId, err)
+	}
+}
+
+func (s *Scheduler) DeleteMessagesScheduleByApplication(appID uint) {
 	var messages []*model.Message
 	s.db.DB.Where("application_id = ?", appID).Find(&messages)
 	for _, message := range messages {
@@ -88,13 +446,19 @@ func (s Scheduler) DeleteMessagesScheduleByApplication(appID uint) {
 	}
 }
 
-func (s Scheduler) DeleteMessagesScheduleByUser(userID uint) {
-	app, _ := s.db.GetApplicationsByUser(userID)
-	for _, app := range app {
+func (s *Scheduler) DeleteMessagesScheduleByUser(userID uint) {
+	apps, _ := s.db.GetApplicationsByUser(userID)
+	for _, app := range apps {
 		s.DeleteMessagesScheduleByApplication(app.ID)
 	}
 }
 
+// ListScheduled returns the pending scheduled jobs belonging to a user so the
+// API/frontend can render them.
+func (s *Scheduler) ListScheduled(userID uint) ([]*ScheduledJob, error) {
+	return s.store.ListByUser(userID)
+}
+
 // moved from api/message.go due to circular imports
 func ToExternalMessage(msg *model.Message) *model.MessageExternal {
 	res := &model.MessageExternal{
@@ -105,6 +469,7 @@ func ToExternalMessage(msg *model.Message) *model.MessageExternal {
 		Priority:      &msg.Priority,
 		Date:          msg.Date,
 		PostponedAt:   msg.PostponedAt,
+		Schedule:      msg.Schedule,
 	}
 	if len(msg.Extras) != 0 {
 		res.Extras = make(map[string]interface{})
@@ -112,3 +477,30 @@ func ToExternalMessage(msg *model.Message) *model.MessageExternal {
 	}
 	return res
 }
+
+var iso8601DurationPattern = regexp.MustCompile(`^P(?:(\d+)Y)?(?:(\d+)M)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// parseISO8601Duration parses a subset of ISO-8601 durations (e.g. "PT2H",
+// "P1DT12H", "PT90M") into a time.Duration. Years/months are approximated as
+// 365/30 days respectively, which is precise enough for reminder-style
+// schedules.
+func parseISO8601Duration(s string) (time.Duration, error) {
+	matches := iso8601DurationPattern.FindStringSubmatch(s)
+	if matches == nil || s == "P" || s == "PT" {
+		return 0, ErrInvalidSchedule
+	}
+
+	var duration time.Duration
+	units := []time.Duration{365 * 24 * time.Hour, 30 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+	for i, match := range matches[1:] {
+		if match == "" {
+			continue
+		}
+		value, err := strconv.Atoi(match)
+		if err != nil {
+			return 0, ErrInvalidSchedule
+		}
+		duration += time.Duration(value) * units[i]
+	}
+	return duration, nil
+}
@@ -0,0 +1,336 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gotify/server/v2/api/stream"
+	"github.com/gotify/server/v2/database"
+	"github.com/gotify/server/v2/model"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newTestUUID() uuid.UUID {
+	return uuid.New()
+}
+
+// mockTimeNow overrides the package-level timeNow (used throughout the
+// scheduler to decide what's "now") and returns a func to restore it.
+func mockTimeNow(at time.Time) func() {
+	original := timeNow
+	timeNow = func() time.Time { return at }
+	return func() { timeNow = original }
+}
+
+func TestClassifyJobs(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	onTimeJob := &ScheduledJob{MessageID: 1, NextFireAt: now.Add(time.Hour)}
+	missedJob := &ScheduledJob{MessageID: 2, NextFireAt: now.Add(-time.Hour)}
+
+	onTime, missed := classifyJobs([]*ScheduledJob{onTimeJob, missedJob}, now)
+
+	if len(onTime) != 1 || onTime[0] != onTimeJob {
+		t.Fatalf("expected onTime to contain only the future job, got %+v", onTime)
+	}
+	if len(missed) != 1 || missed[0] != missedJob {
+		t.Fatalf("expected missed to contain only the elapsed job, got %+v", missed)
+	}
+}
+
+// setupTestDB returns an in-memory GormDatabase with the scheduled_jobs
+// table migrated alongside the message/application/user tables.
+func setupTestDB(t *testing.T) *database.GormDatabase {
+	t.Helper()
+	gormDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	// :memory: is private per-connection; force a single connection so every
+	// query in this test sees the same database.
+	sqlDB, err := gormDB.DB()
+	if err != nil {
+		t.Fatalf("failed to get underlying sql.DB: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+
+	db := &database.GormDatabase{DB: gormDB}
+	if err := db.DB.AutoMigrate(&model.User{}, &model.Application{}, &model.Message{}, &ScheduledJob{}); err != nil {
+		t.Fatalf("failed to migrate test db: %v", err)
+	}
+	return db
+}
+
+func createTestApplication(t *testing.T, db *database.GormDatabase) *model.Application {
+	t.Helper()
+	app := &model.Application{UserID: 1, Name: "test app"}
+	if err := db.DB.Create(app).Error; err != nil {
+		t.Fatalf("failed to create application: %v", err)
+	}
+	return app
+}
+
+func createTestMessage(t *testing.T, db *database.GormDatabase, appID uint, schedule *string) *model.Message {
+	t.Helper()
+	msg := &model.Message{ApplicationID: appID, Message: "hi", Title: "title", Date: time.Now(), Schedule: schedule}
+	if err := db.DB.Create(msg).Error; err != nil {
+		t.Fatalf("failed to create message: %v", err)
+	}
+	return msg
+}
+
+func messageCount(t *testing.T, db *database.GormDatabase, appID uint) int64 {
+	t.Helper()
+	var count int64
+	if err := db.DB.Model(&model.Message{}).Where("application_id = ?", appID).Count(&count).Error; err != nil {
+		t.Fatalf("failed to count messages: %v", err)
+	}
+	return count
+}
+
+// TestReconcile_Recurring_FireImmediately simulates downtime by persisting a
+// recurring job with a NextFireAt in the past and the default
+// (fire_immediately) misfire policy, then advancing timeNow past it and
+// booting the scheduler: the missed occurrence must fire synchronously
+// during reconcile, and the job's bookkeeping must reflect the fire.
+func TestReconcile_Recurring_FireImmediately(t *testing.T) {
+	db := setupTestDB(t)
+	app := createTestApplication(t, db)
+	schedule := "PT1H"
+	msg := createTestMessage(t, db, app.ID, &schedule)
+
+	past := time.Now().Add(-2 * time.Hour)
+	job := &ScheduledJob{ID: newTestUUID(), MessageID: msg.ID, UserID: app.UserID, Kind: KindCron, Schedule: schedule, NextFireAt: past, MisfirePolicy: MisfireFireImmediately}
+	store := NewJobStore(db)
+	if err := store.Create(job); err != nil {
+		t.Fatalf("failed to persist job: %v", err)
+	}
+
+	restore := mockTimeNow(past.Add(3 * time.Hour))
+	defer restore()
+
+	streamAPI := stream.New(time.Second, time.Second, nil, db)
+	_, shutdown := Init(db, streamAPI)
+	defer shutdown()
+
+	if count := messageCount(t, db, app.ID); count != 2 {
+		t.Fatalf("expected the missed occurrence to fire immediately, creating one extra message; got %d messages", count)
+	}
+
+	updated, err := store.GetByMessageID(msg.ID)
+	if err != nil || updated == nil {
+		t.Fatalf("expected job to still be persisted after firing, err=%v", err)
+	}
+	if updated.LastFiredAt == nil {
+		t.Fatal("expected LastFiredAt to be set after firing")
+	}
+	if !updated.NextFireAt.After(timeNow()) {
+		t.Fatalf("expected NextFireAt to be advanced into the future, got %v", updated.NextFireAt)
+	}
+}
+
+// TestReconcile_Recurring_Skip simulates downtime for a recurring job whose
+// misfire policy is skip: the missed occurrence must NOT fire, but the job
+// must still be re-registered so future occurrences continue normally.
+func TestReconcile_Recurring_Skip(t *testing.T) {
+	db := setupTestDB(t)
+	app := createTestApplication(t, db)
+	schedule := "PT1H"
+	msg := createTestMessage(t, db, app.ID, &schedule)
+
+	past := time.Now().Add(-2 * time.Hour)
+	job := &ScheduledJob{ID: newTestUUID(), MessageID: msg.ID, UserID: app.UserID, Kind: KindCron, Schedule: schedule, NextFireAt: past, MisfirePolicy: MisfireSkip}
+	store := NewJobStore(db)
+	if err := store.Create(job); err != nil {
+		t.Fatalf("failed to persist job: %v", err)
+	}
+
+	restore := mockTimeNow(past.Add(3 * time.Hour))
+	defer restore()
+
+	streamAPI := stream.New(time.Second, time.Second, nil, db)
+	_, shutdown := Init(db, streamAPI)
+	defer shutdown()
+
+	if count := messageCount(t, db, app.ID); count != 1 {
+		t.Fatalf("expected skip to not fire the missed occurrence; got %d messages", count)
+	}
+
+	updated, err := store.GetByMessageID(msg.ID)
+	if err != nil || updated == nil {
+		t.Fatalf("expected skipped recurring job to remain registered, err=%v", err)
+	}
+	if updated.LastFiredAt != nil {
+		t.Fatal("expected LastFiredAt to stay unset when the misfire policy is skip")
+	}
+}
+
+// TestReconcile_OneTime_Skip verifies a missed one-shot job with the skip
+// policy is dropped rather than fired or kept around.
+func TestReconcile_OneTime_Skip(t *testing.T) {
+	db := setupTestDB(t)
+	app := createTestApplication(t, db)
+	msg := createTestMessage(t, db, app.ID, nil)
+
+	past := time.Now().Add(-2 * time.Hour)
+	job := &ScheduledJob{ID: newTestUUID(), MessageID: msg.ID, UserID: app.UserID, Kind: KindOneTime, NextFireAt: past, MisfirePolicy: MisfireSkip}
+	store := NewJobStore(db)
+	if err := store.Create(job); err != nil {
+		t.Fatalf("failed to persist job: %v", err)
+	}
+
+	restore := mockTimeNow(past.Add(3 * time.Hour))
+	defer restore()
+
+	streamAPI := stream.New(time.Second, time.Second, nil, db)
+	_, shutdown := Init(db, streamAPI)
+	defer shutdown()
+
+	updated, err := store.GetByMessageID(msg.ID)
+	if err != nil {
+		t.Fatalf("unexpected error looking up job: %v", err)
+	}
+	if updated != nil {
+		t.Fatal("expected a skipped one-shot job to be dropped from the store")
+	}
+}
+
+// TestReconcile_OneTime_FireImmediately verifies a missed one-shot job fired
+// synchronously during reconcile has its persisted row removed (so it isn't
+// re-classified as missed and re-fired on the next boot) and its
+// postponed_at cleared.
+func TestReconcile_OneTime_FireImmediately(t *testing.T) {
+	db := setupTestDB(t)
+	app := createTestApplication(t, db)
+	msg := createTestMessage(t, db, app.ID, nil)
+	postponedAt := time.Now().Add(-2 * time.Hour)
+	if err := db.UpdateMessagePostponement(msg.ID, &postponedAt); err != nil {
+		t.Fatalf("failed to postpone message: %v", err)
+	}
+
+	job := &ScheduledJob{ID: newTestUUID(), MessageID: msg.ID, UserID: app.UserID, Kind: KindOneTime, NextFireAt: postponedAt, MisfirePolicy: MisfireFireImmediately}
+	store := NewJobStore(db)
+	if err := store.Create(job); err != nil {
+		t.Fatalf("failed to persist job: %v", err)
+	}
+
+	restore := mockTimeNow(postponedAt.Add(3 * time.Hour))
+	defer restore()
+
+	streamAPI := stream.New(time.Second, time.Second, nil, db)
+	_, shutdown := Init(db, streamAPI)
+	defer shutdown()
+
+	updated, err := store.GetByMessageID(msg.ID)
+	if err != nil {
+		t.Fatalf("unexpected error looking up job: %v", err)
+	}
+	if updated != nil {
+		t.Fatal("expected the fired one-shot job's persisted row to be deleted")
+	}
+
+	var message model.Message
+	if err := db.DB.Where("id = ?", msg.ID).First(&message).Error; err != nil {
+		t.Fatalf("failed to reload message: %v", err)
+	}
+	if message.PostponedAt != nil {
+		t.Fatal("expected postponed_at to be cleared after firing")
+	}
+}
+
+// TestReconcile_OneTime_FireOnceThenResume verifies the same cleanup happens
+// for the deferred catch-up fire used by fire_once_then_resume.
+func TestReconcile_OneTime_FireOnceThenResume(t *testing.T) {
+	db := setupTestDB(t)
+	app := createTestApplication(t, db)
+	msg := createTestMessage(t, db, app.ID, nil)
+	postponedAt := time.Now().Add(-2 * time.Hour)
+	if err := db.UpdateMessagePostponement(msg.ID, &postponedAt); err != nil {
+		t.Fatalf("failed to postpone message: %v", err)
+	}
+
+	job := &ScheduledJob{ID: newTestUUID(), MessageID: msg.ID, UserID: app.UserID, Kind: KindOneTime, NextFireAt: postponedAt, MisfirePolicy: MisfireFireOnceThenResume}
+	store := NewJobStore(db)
+	if err := store.Create(job); err != nil {
+		t.Fatalf("failed to persist job: %v", err)
+	}
+
+	restore := mockTimeNow(postponedAt.Add(3 * time.Hour))
+	defer restore()
+
+	streamAPI := stream.New(time.Second, time.Second, nil, db)
+	_, shutdown := Init(db, streamAPI)
+	defer shutdown()
+
+	time.Sleep(1500 * time.Millisecond)
+
+	updated, err := store.GetByMessageID(msg.ID)
+	if err != nil {
+		t.Fatalf("unexpected error looking up job: %v", err)
+	}
+	if updated != nil {
+		t.Fatal("expected the deferred catch-up fire to delete the persisted job row")
+	}
+
+	var message model.Message
+	if err := db.DB.Where("id = ?", msg.ID).First(&message).Error; err != nil {
+		t.Fatalf("failed to reload message: %v", err)
+	}
+	if message.PostponedAt != nil {
+		t.Fatal("expected postponed_at to be cleared after the catch-up fire")
+	}
+}
+
+// TestReconcile_Recurring_FireOnceThenResume verifies the catch-up fire is
+// deferred rather than happening synchronously during reconcile, so the
+// scheduler can resume its normal loop first.
+func TestReconcile_Recurring_FireOnceThenResume(t *testing.T) {
+	db := setupTestDB(t)
+	app := createTestApplication(t, db)
+	schedule := "PT1H"
+	msg := createTestMessage(t, db, app.ID, &schedule)
+
+	past := time.Now().Add(-2 * time.Hour)
+	job := &ScheduledJob{ID: newTestUUID(), MessageID: msg.ID, UserID: app.UserID, Kind: KindCron, Schedule: schedule, NextFireAt: past, MisfirePolicy: MisfireFireOnceThenResume}
+	store := NewJobStore(db)
+	if err := store.Create(job); err != nil {
+		t.Fatalf("failed to persist job: %v", err)
+	}
+
+	restore := mockTimeNow(past.Add(3 * time.Hour))
+	defer restore()
+
+	streamAPI := stream.New(time.Second, time.Second, nil, db)
+	_, shutdown := Init(db, streamAPI)
+	defer shutdown()
+
+	if count := messageCount(t, db, app.ID); count != 1 {
+		t.Fatalf("expected the catch-up fire to be deferred rather than synchronous; got %d messages immediately after boot", count)
+	}
+}
+
+// TestScheduleRecurringWithPolicy_PersistsChosenPolicy verifies the misfire
+// policy is actually configurable per job, instead of every job always being
+// persisted with DefaultMisfirePolicy.
+func TestScheduleRecurringWithPolicy_PersistsChosenPolicy(t *testing.T) {
+	db := setupTestDB(t)
+	app := createTestApplication(t, db)
+	msg := createTestMessage(t, db, app.ID, nil)
+
+	streamAPI := stream.New(time.Second, time.Second, nil, db)
+	s, shutdown := Init(db, streamAPI)
+	defer shutdown()
+
+	if err := s.ScheduleRecurringWithPolicy(msg.ID, "PT1H", MisfireSkip); err != nil {
+		t.Fatalf("failed to schedule recurring job: %v", err)
+	}
+
+	persisted, err := s.store.GetByMessageID(msg.ID)
+	if err != nil || persisted == nil {
+		t.Fatalf("expected job to be persisted, err=%v", err)
+	}
+	if persisted.MisfirePolicy != MisfireSkip {
+		t.Fatalf("expected persisted misfire policy to be %q, got %q", MisfireSkip, persisted.MisfirePolicy)
+	}
+}